@@ -0,0 +1,126 @@
+package faketickers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the minimal time API both RealClock and FakeClock
+// implement, so library code can depend on a Clock instead of calling
+// the time package directly and still be testable. Unlike the
+// FakeTickers/StartTimers monkey patching, a FakeClock requires the
+// code under test to be written against this interface.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) *time.Ticker
+	NewTimer(d time.Duration) *time.Timer
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+	AfterFunc(d time.Duration, f func()) *time.Timer
+}
+
+// RealClock implements Clock by delegating straight to the time
+// package.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time                                  { return time.Now() }
+func (RealClock) NewTicker(d time.Duration) *time.Ticker          { return time.NewTicker(d) }
+func (RealClock) NewTimer(d time.Duration) *time.Timer            { return time.NewTimer(d) }
+func (RealClock) After(d time.Duration) <-chan time.Time          { return time.After(d) }
+func (RealClock) Sleep(d time.Duration)                           { time.Sleep(d) }
+func (RealClock) AfterFunc(d time.Duration, f func()) *time.Timer { return time.AfterFunc(d, f) }
+
+// FakeClock is a Clock backed by the same ticker/timer machinery as
+// FakeTickers, but without patching the time package: code under test
+// must accept a Clock and call its methods rather than time.NewTicker
+// et al. directly. Use this when bou.ke/monkey's runtime patching
+// isn't available (some Go versions/platforms/architectures, or
+// environments that disable W^X) or isn't acceptable, while keeping
+// the same Tick/Advance/BlockUntil/tag vocabulary as FakeTickers.
+type FakeClock struct {
+	ft *FakeTickers
+}
+
+// NewFakeClock creates a ready-to-use FakeClock.
+func NewFakeClock() *FakeClock {
+	ft := &FakeTickers{}
+	ft.tickerCond = sync.NewCond(&ft.tickerMut)
+	ft.tickers = []taggedTicker{}
+	ft.initTimers()
+	return &FakeClock{ft: ft}
+}
+
+func (c *FakeClock) Now() time.Time                                  { return c.ft.Now() }
+func (c *FakeClock) NewTicker(d time.Duration) *time.Ticker          { return c.ft.newTicker(d) }
+func (c *FakeClock) NewTimer(d time.Duration) *time.Timer            { return c.ft.newTimer(d) }
+func (c *FakeClock) After(d time.Duration) <-chan time.Time          { return c.ft.after(d) }
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) *time.Timer { return c.ft.afterFunc(d, f) }
+
+// Sleep blocks the caller until the fake clock is advanced at least d
+// past the current virtual time.
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.NewTimer(d).C
+}
+
+// Tick sends one tick down all fake tickers created since
+// NewFakeClock (or, with the optional tag argument, only those with
+// that tag). See FakeTickers.Tick.
+func (c *FakeClock) Tick(tag ...string) { c.ft.Tick(tag...) }
+
+// Tag sets the given string tag on all subsequent NewTicker calls. See
+// FakeTickers.Tag.
+func (c *FakeClock) Tag(tag string) { c.ft.Tag(tag) }
+
+// Wait blocks until at least minTickers tickers have been created. See
+// FakeTickers.Wait.
+func (c *FakeClock) Wait(minTickers int, timeoutInterval ...time.Duration) error {
+	return c.ft.Wait(minTickers, timeoutInterval...)
+}
+
+// WaitContext is like Wait but gives up when ctx is done instead of
+// after a fixed timeout. See FakeTickers.WaitContext.
+func (c *FakeClock) WaitContext(ctx context.Context, minTickers int) error {
+	return c.ft.WaitContext(ctx, minTickers)
+}
+
+// BlockUntil blocks until at least minTickers tickers (of any tag)
+// have been created and their creating goroutines have reached a
+// channel receive. See FakeTickers.BlockUntil.
+func (c *FakeClock) BlockUntil(minTickers int) { c.ft.BlockUntil(minTickers) }
+
+// BlockUntilTag is like BlockUntil but only counts tickers created
+// with the given tag. See FakeTickers.BlockUntilTag.
+func (c *FakeClock) BlockUntilTag(tag string, minTickers int) { c.ft.BlockUntilTag(tag, minTickers) }
+
+// Advance moves the fake clock forward by d, firing any fake timers
+// that come due. See FakeTickers.Advance.
+func (c *FakeClock) Advance(d time.Duration) { c.ft.Advance(d) }
+
+// SetNow sets the fake clock's virtual time directly. See
+// FakeTickers.SetNow.
+func (c *FakeClock) SetNow(tm time.Time) { c.ft.SetNow(tm) }
+
+var (
+	defaultClockMut sync.Mutex
+	defaultClock    Clock = RealClock{}
+)
+
+// SetDefault sets the Clock returned by Default. Libraries that want
+// to opt into Clock-based faking without requiring a constructor
+// argument can call faketickers.Default() instead of the time package
+// directly, and tests can swap in a FakeClock for the duration of the
+// test.
+func SetDefault(c Clock) {
+	defaultClockMut.Lock()
+	defer defaultClockMut.Unlock()
+	defaultClock = c
+}
+
+// Default returns the package-level Clock, which is a RealClock
+// unless SetDefault has been called.
+func Default() Clock {
+	defaultClockMut.Lock()
+	defer defaultClockMut.Unlock()
+	return defaultClock
+}
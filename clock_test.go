@@ -0,0 +1,55 @@
+package faketickers_test
+
+import (
+	"time"
+
+	faketickers "github.com/allenluce/faketickers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Clock", func() {
+	It("RealClock delegates to the time package", func() {
+		var c faketickers.Clock = faketickers.RealClock{}
+		before := time.Now()
+		Ω(c.Now()).Should(BeTemporally(">=", before))
+		Eventually(c.After(time.Millisecond)).Should(Receive())
+	})
+	It("FakeClock tickers only fire on Tick", func() {
+		var c faketickers.Clock = faketickers.NewFakeClock()
+		fc := c.(*faketickers.FakeClock)
+		ticker := c.NewTicker(time.Minute)
+		fc.BlockUntil(1)
+		received := make(chan time.Time, 1)
+		go func() { received <- <-ticker.C }()
+		Consistently(received).ShouldNot(Receive())
+		fc.Tick()
+		Eventually(received).Should(Receive())
+	})
+	It("FakeClock timers fire in order on Advance", func() {
+		fc := faketickers.NewFakeClock()
+		var order []string
+		fc.AfterFunc(time.Minute, func() { order = append(order, "slow") })
+		fc.AfterFunc(time.Second, func() { order = append(order, "fast") })
+		fc.Advance(time.Minute)
+		Ω(order).Should(Equal([]string{"fast", "slow"}))
+	})
+	It("FakeClock Sleep blocks until Advance", func() {
+		fc := faketickers.NewFakeClock()
+		done := make(chan bool, 1)
+		go func() {
+			fc.Sleep(time.Second)
+			done <- true
+		}()
+		Consistently(done).ShouldNot(Receive())
+		fc.Advance(time.Second)
+		Eventually(done).Should(Receive(BeTrue()))
+	})
+	It("SetDefault/Default swap the package-level Clock", func() {
+		Ω(faketickers.Default()).Should(Equal(faketickers.RealClock{}))
+		fc := faketickers.NewFakeClock()
+		faketickers.SetDefault(fc)
+		defer faketickers.SetDefault(faketickers.RealClock{})
+		Ω(faketickers.Default()).Should(BeIdenticalTo(fc))
+	})
+})
@@ -41,11 +41,11 @@ objects that give you control over time.
 Note that FakeTickers must be initialized before the call to
 time.NewTicker() -- it'll only hand out fake tickers then, not replace
 existing tickers.
-
 */
 package faketickers
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -55,18 +55,74 @@ import (
 
 type taggedTicker struct {
 	Tag    string
+	Mode   TickerMode
 	Ticker *chan time.Time
+
+	// Scope is the goroutine ID a Scope() was called on if this
+	// ticker was created by that scope's goroutine subtree, or 0 if
+	// it was created outside of any scope. See scope.go.
+	Scope uint64
+
+	// Creator is the ID of the goroutine that called NewTicker. Used
+	// by BlockUntil/BlockUntilTag to tell whether that goroutine has
+	// actually reached its <-ticker.C, not just that NewTicker
+	// returned. See parkedCount.
+	Creator uint64
 }
 
+// TickerMode controls how a fake ticker's channel behaves when a tick
+// is sent, see Mode.
+//
+// There is deliberately no notion of a ticker's period here: every
+// fake ticker is driven entirely by explicit Tick/TickN calls, never
+// by its own duration, so Reset(d) on a fake ticker's *time.Ticker --
+// unlike the real thing -- has no effect on when or how often it
+// receives ticks. Only the drop-on-overrun vs. must-consume-each
+// distinction between ModeStrict and ModeCoalescing is implemented.
+type TickerMode int
+
+const (
+	// ModeStrict, the default, sends ticks with a blocking send: the
+	// receiver must consume each one, and Tick/TickN won't return
+	// until it has.
+	ModeStrict TickerMode = iota
+	// ModeCoalescing mirrors time.Ticker's own buffered-size-1
+	// behavior: a tick sent while the previous one is still pending
+	// is dropped rather than queued or blocked on.
+	ModeCoalescing
+)
+
 // FakeTickers provides a flexible ticker system.
 type FakeTickers struct {
-	tickers   []taggedTicker
-	guard     *monkey.PatchGuard
-	tag       string
-	tickerMut sync.Mutex
-	immediate bool
-	done      chan interface{}
-	wg        *sync.WaitGroup
+	tickers    []taggedTicker
+	guard      *monkey.PatchGuard
+	tag        string
+	mode       TickerMode
+	tickerMut  sync.Mutex
+	tickerCond *sync.Cond
+	immediate  bool
+	done       chan interface{}
+	wg         *sync.WaitGroup
+
+	// Timer/After/AfterFunc support, see timers.go.
+	timerMut       sync.Mutex
+	timerQueue     timerQueue
+	now            time.Time
+	timerGuard     *monkey.PatchGuard
+	afterGuard     *monkey.PatchGuard
+	afterFuncGuard *monkey.PatchGuard
+
+	// Logical ticker source support, see logical.go. Protected by
+	// tickerMut, same as tickers.
+	sources []*logicalTickerSource
+
+	// Scope() support, see scope.go. scopes is only ever populated on
+	// a registryRoot() and protected by tickerMut, same as tickers.
+	// scopeParent and scopeRoot are only ever set on a FakeTickers
+	// returned by Scope(), never on the root itself.
+	scopes      map[uint64]*scopeSettings
+	scopeParent *FakeTickers
+	scopeRoot   uint64
 }
 
 // NewFakeTicker creates and starts the fake tickers
@@ -79,13 +135,55 @@ func NewFakeTicker(immediate ...bool) *FakeTickers {
 	return ft
 }
 
+// newTicker hands out a genuine, real time.Ticker (momentarily
+// unpatching time.NewTicker to create it) rather than a fabricated
+// one, so that calling Stop/Reset on it is as safe as on any real
+// ticker. The real ticker is stopped immediately -- FakeTickers never
+// lets it fire on its own, only Tick/TickN do that -- and its C field
+// is swapped for our own fake channel, buffered if the ticker is in
+// ModeCoalescing. Neither Stop nor Reset affects that fake channel:
+// Tick/TickN keep sending on it after Stop, and Reset doesn't change
+// when it receives ticks (see TickerMode).
+//
+// guard is nil for a FakeClock, which calls this directly instead of
+// going through the time.NewTicker patch.
 func (t *FakeTickers) newTicker(d time.Duration) *time.Ticker {
 	defer t.tickerMut.Unlock()
 	t.tickerMut.Lock()
-	var ticker time.Ticker
-	c := make(chan time.Time)
-	ticker.C = c
-	t.tickers = append(t.tickers, taggedTicker{Tag: t.tag, Ticker: &c})
+
+	if t.guard != nil {
+		t.guard.Unpatch()
+	}
+	real := time.NewTicker(d)
+	if t.guard != nil {
+		t.guard.Restore()
+	}
+	real.Stop()
+
+	gid := goroutineID()
+	tag, mode, scope := t.tag, t.mode, uint64(0)
+	if len(t.scopes) > 0 {
+		ancestry := goroutineAncestry()
+		for root, settings := range t.scopes {
+			if descendsFrom(ancestry, root, gid) {
+				tag, mode, scope = settings.tag, settings.mode, root
+				break
+			}
+		}
+	}
+
+	bufSize := 0
+	if mode == ModeCoalescing {
+		bufSize = 1
+	}
+	c := make(chan time.Time, bufSize)
+	real.C = c
+
+	t.tickers = append(t.tickers, taggedTicker{Tag: tag, Mode: mode, Ticker: &c, Scope: scope, Creator: gid})
+	t.tickerCond.Broadcast()
+	for _, source := range t.sources {
+		source.subscribe(tag, &c)
+	}
 	if t.immediate { // Tick quickly and forever.
 		t.wg.Add(1)
 		go func(c chan time.Time) {
@@ -100,7 +198,7 @@ func (t *FakeTickers) newTicker(d time.Duration) *time.Ticker {
 			}
 		}(c)
 	}
-	return &ticker
+	return real
 }
 
 // Start initializes the fake tickers and replaces time.NewTicker()
@@ -108,30 +206,107 @@ func (t *FakeTickers) newTicker(d time.Duration) *time.Ticker {
 func (t *FakeTickers) Start() {
 	t.done = make(chan interface{})
 	t.wg = &sync.WaitGroup{}
+	t.tickerCond = sync.NewCond(&t.tickerMut)
 	t.guard = monkey.Patch(time.NewTicker, t.newTicker)
 	t.tickers = []taggedTicker{}
+	t.scopes = nil
+	t.initTimers()
 }
 
 // Tick will send one tick down all tickers created since Start() was
 // called. If given the optional tag argument, it will only send ticks
-// to those NewTickers that have that tag.
+// to those NewTickers that have that tag. On a FakeTickers returned by
+// Scope(), only tickers created within that scope are considered.
 func (t *FakeTickers) Tick(tag ...string) {
 	if len(tag) == 0 {
 		tag = []string{""}
 	}
 	now := time.Now()
-	for _, ticker := range t.tickers {
+	for _, ticker := range t.visibleTickers() {
 		if ticker.Tag == tag[0] {
-			*ticker.Ticker <- now
+			sendTick(ticker.Ticker, ticker.Mode, now)
+		}
+	}
+}
+
+// visibleTickers returns the tickers this FakeTickers can see: for the
+// root, every ticker not claimed by some Scope(); for a scope, only
+// those created within its own Scope() subtree. The two views
+// partition root.tickers rather than overlap, so a root-level Tick
+// never blocks trying to deliver to a ticker only a Scope() knows
+// about (and vice versa).
+func (t *FakeTickers) visibleTickers() []taggedTicker {
+	root := t.registryRoot()
+	var visible []taggedTicker
+	for _, ticker := range root.tickers {
+		if ticker.Scope == t.scopeRoot {
+			visible = append(visible, ticker)
 		}
 	}
+	return visible
 }
 
-// Tag sets the given string tag on all subsequent NewTicker()
-// calls. Set a tag before a NewTicker() call when you want to control
-// that ticker separately.
+// TickN sends n ticks in sequence to all tickers created since
+// Start(), filtered by the optional tag argument exactly as Tick is.
+// ModeStrict tickers (the default) see every tick, since each send
+// blocks until consumed; ModeCoalescing tickers may see fewer, since
+// a tick sent while the previous one is still pending is dropped --
+// matching how a slow consumer of a real time.Ticker misses ticks
+// rather than queuing them.
+func (t *FakeTickers) TickN(n int, tag ...string) {
+	for i := 0; i < n; i++ {
+		t.Tick(tag...)
+	}
+}
+
+// sendTick delivers one tick to a fake ticker's channel, according to
+// its mode.
+func sendTick(c *chan time.Time, mode TickerMode, now time.Time) {
+	if mode == ModeCoalescing {
+		select {
+		case *c <- now:
+		default: // Receiver hasn't consumed the last one; drop this tick.
+		}
+		return
+	}
+	*c <- now
+}
+
+// Tag sets the given string tag on all subsequent NewTicker() calls
+// not made within some Scope() -- or, on a FakeTickers returned by
+// Scope(), on those made within that scope. Set a tag before a
+// NewTicker() call when you want to control that ticker separately.
 func (t *FakeTickers) Tag(tag string) {
-	t.tag = tag
+	if t.scopeRoot == 0 {
+		t.tag = tag
+		return
+	}
+	t.scopeSettingsLocked(func(s *scopeSettings) { s.tag = tag })
+}
+
+// Mode sets the TickerMode on all subsequent NewTicker() calls not
+// made within some Scope() -- or, on a FakeTickers returned by
+// Scope(), on those made within that scope. Set a mode before a
+// NewTicker() call when you want that ticker's Tick sends to coalesce
+// rather than block.
+func (t *FakeTickers) Mode(mode TickerMode) {
+	if t.scopeRoot == 0 {
+		t.mode = mode
+		return
+	}
+	t.scopeSettingsLocked(func(s *scopeSettings) { s.mode = mode })
+}
+
+// scopeSettingsLocked runs f against this Scope()'s settings, held in
+// its root's scopes map, while the root's tickerMut is held -- the
+// same lock newTicker reads the map under.
+func (t *FakeTickers) scopeSettingsLocked(f func(*scopeSettings)) {
+	root := t.registryRoot()
+	root.tickerMut.Lock()
+	defer root.tickerMut.Unlock()
+	if s, ok := root.scopes[t.scopeRoot]; ok {
+		f(s)
+	}
 }
 
 // Stop closes all the existing ticker channels and restores
@@ -140,31 +315,185 @@ func (t *FakeTickers) Stop() {
 	close(t.done)
 	t.wg.Wait()
 	t.guard.Unpatch()
+	t.stopTimers()
+	for _, source := range t.sources {
+		source.stop()
+	}
 	for _, ticker := range t.tickers {
 		close(*ticker.Ticker)
 	}
 }
 
-const pollingInterval = time.Millisecond * 10
+// realAfter behaves like time.After but is immune to the
+// time.NewTimer/time.After patches FakeTickers installs, since Wait's
+// own timeout must run against real wall-clock time even while a
+// FakeTickers is active.
+func realAfter(d time.Duration) <-chan time.Time {
+	c := make(chan time.Time, 1)
+	go func() {
+		time.Sleep(d)
+		c <- time.Now()
+	}()
+	return c
+}
 
 // Wait blocks until the total number of calls to NewTicker is equal
-// or greater than minTickers or until timeout.  Use when you don't want to
-// proceed until the intended code has its ticker(s) set up.
+// or greater than minTickers or until timeout. Use when you don't want
+// to proceed until the intended code has its ticker(s) set up. On a
+// FakeTickers returned by Scope(), only NewTicker calls made within
+// that scope count.
 func (t *FakeTickers) Wait(minTickers int, timeoutInterval ...time.Duration) error {
-	var timeout <-chan time.Time
+	var giveUp <-chan struct{}
 	if len(timeoutInterval) > 0 {
-		timeout = time.After(timeoutInterval[0])
+		done := make(chan struct{})
+		giveUp = done
+		go func() {
+			<-realAfter(timeoutInterval[0])
+			close(done)
+		}()
+	}
+	if !t.waitLocked(minTickers, giveUp) {
+		return fmt.Errorf("Timeout, only %d NewTicker calls (not %d)", len(t.visibleTickers()), minTickers)
 	}
-	for {
+	return nil
+}
+
+// WaitContext is like Wait but gives up as soon as ctx is done instead
+// of after a fixed timeout, returning ctx.Err().
+func (t *FakeTickers) WaitContext(ctx context.Context, minTickers int) error {
+	if !t.waitLocked(minTickers, ctx.Done()) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// waitLocked blocks until this view has at least minTickers tickers
+// or giveUp fires (which may be nil, meaning wait forever), waking
+// immediately on either via the root's tickerCond rather than polling.
+func (t *FakeTickers) waitLocked(minTickers int, giveUp <-chan struct{}) bool {
+	root := t.registryRoot()
+	root.tickerMut.Lock()
+	defer root.tickerMut.Unlock()
+
+	if t.countLocked(nil) >= minTickers {
+		return true
+	}
+	if giveUp == nil {
+		for t.countLocked(nil) < minTickers {
+			root.tickerCond.Wait()
+		}
+		return true
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
 		select {
-		case <-time.After(pollingInterval):
-			if len(t.tickers) >= minTickers {
-				return nil
-			}
-		case <-timeout:
-			return fmt.Errorf("Timeout, only %d NewTicker calls (not %d)", len(t.tickers), minTickers)
+		case <-giveUp:
+			root.tickerMut.Lock()
+			root.tickerCond.Broadcast()
+			root.tickerMut.Unlock()
+		case <-done:
+		}
+	}()
+	for t.countLocked(nil) < minTickers {
+		select {
+		case <-giveUp:
+			return false
+		default:
+		}
+		root.tickerCond.Wait()
+	}
+	return true
+}
+
+// BlockUntil blocks until at least minTickers tickers (of any tag)
+// have been created since Start() was called AND each of their
+// creating goroutines has actually reached a channel receive -- not
+// just that NewTicker has returned. A NewTicker call is only ever
+// followed by other setup work for a moment before the caller blocks
+// on <-ticker.C, but Tick() can race that moment, firing before the
+// receiver is parked to receive it; BlockUntil closes that window. On
+// a FakeTickers returned by Scope(), only tickers created within that
+// scope count.
+//
+// This is necessarily a heuristic: runtime.Stack reports that a
+// goroutine is blocked in "chan receive", not which channel, so a
+// creator goroutine parked on some other channel (a select among
+// several, or an unrelated receive) is indistinguishable from one
+// parked on its own ticker. For the common case -- NewTicker()
+// immediately followed by a plain <-ticker.C -- this is exact. A
+// ticker created by the very goroutine calling BlockUntil can't also
+// be parked on its own receive right now (it's busy calling
+// BlockUntil), so it's counted as soon as it exists, same as before --
+// that pattern is explicit synchronization, not the "did my spawned
+// goroutine get there yet" race this guards against.
+func (t *FakeTickers) BlockUntil(minTickers int) {
+	t.blockUntil(nil, minTickers)
+}
+
+// BlockUntilTag is like BlockUntil but only counts tickers created
+// with the given tag.
+func (t *FakeTickers) BlockUntilTag(tag string, minTickers int) {
+	t.blockUntil(&tag, minTickers)
+}
+
+func (t *FakeTickers) blockUntil(tag *string, minTickers int) {
+	root := t.registryRoot()
+	root.tickerMut.Lock()
+	for t.countLocked(tag) < minTickers {
+		root.tickerCond.Wait()
+	}
+	root.tickerMut.Unlock()
+
+	caller := goroutineID()
+	for t.parkedCount(tag, caller) < minTickers {
+		<-realAfter(parkedPollInterval)
+	}
+}
+
+// parkedPollInterval is how often parkedCount re-checks goroutine
+// status while waiting for a ticker's creator to reach its receive.
+// runtime.Stack gives us no way to be woken up the instant a goroutine
+// parks, so this is a short poll rather than an event.
+const parkedPollInterval = time.Millisecond
+
+// parkedCount returns the number of this view's tickers (tagged with
+// *tag, or all of them if tag is nil) that are ready to receive a
+// tick: their creator goroutine is currently blocked in a channel
+// receive or a select, or their creator is the caller itself.
+func (t *FakeTickers) parkedCount(tag *string, caller uint64) int {
+	root := t.registryRoot()
+	root.tickerMut.Lock()
+	tickers := append([]taggedTicker(nil), t.visibleTickers()...)
+	root.tickerMut.Unlock()
+
+	statuses := goroutineStatuses()
+	n := 0
+	for _, ticker := range tickers {
+		if tag != nil && ticker.Tag != *tag {
+			continue
+		}
+		status := statuses[ticker.Creator]
+		if ticker.Creator == caller || status == "chan receive" || status == "select" {
+			n++
+		}
+	}
+	return n
+}
+
+// countLocked returns the number of this view's tickers tagged with
+// *tag, or of all of this view's tickers if tag is nil. It must be
+// called with the registryRoot's tickerMut held.
+func (t *FakeTickers) countLocked(tag *string) int {
+	n := 0
+	for _, ticker := range t.visibleTickers() {
+		if tag != nil && ticker.Tag != *tag {
+			continue
 		}
+		n++
 	}
+	return n
 }
 
 // Sleeper stores the state of the old time.Sleep call for restoration
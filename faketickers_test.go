@@ -1,11 +1,12 @@
 package faketickers_test
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
 
-	. "github.com/allenluce/faketickers"
+	faketickers "github.com/allenluce/faketickers"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -19,7 +20,7 @@ func tickingRoutine(done chan<- bool) bool {
 
 var _ = Describe("Faketicker", func() {
 	It("ticks", func() {
-		ft := FakeTickers{}
+		ft := faketickers.FakeTickers{}
 		ft.Start()
 		done := make(chan bool, 1)
 		var wg sync.WaitGroup
@@ -34,7 +35,7 @@ var _ = Describe("Faketicker", func() {
 		wg.Wait()
 	})
 	It("closes tickers", func() {
-		ft := FakeTickers{}
+		ft := faketickers.FakeTickers{}
 		ft.Start()
 		done := make(chan bool, 1)
 		var wg sync.WaitGroup
@@ -49,7 +50,7 @@ var _ = Describe("Faketicker", func() {
 		wg.Wait()
 	})
 	It("ticks tags", func() {
-		ft := FakeTickers{}
+		ft := faketickers.FakeTickers{}
 		ft.Start()
 
 		done1 := make(chan bool, 1)
@@ -89,7 +90,7 @@ var _ = Describe("Faketicker", func() {
 		wg.Wait()
 	})
 	It("waits until timeout", func() {
-		ft := FakeTickers{}
+		ft := faketickers.FakeTickers{}
 		ft.Start()
 		done := make(chan bool, 1)
 		var wg sync.WaitGroup
@@ -104,8 +105,276 @@ var _ = Describe("Faketicker", func() {
 		Eventually(done).Should(Receive(BeTrue()))
 		wg.Wait()
 	})
+	It("BlockUntil waits for tickers to be created", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		done := make(chan bool, 1)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Ω(tickingRoutine(done)).Should(BeTrue())
+		}()
+		ft.BlockUntil(1)
+		ft.Tick()
+		Eventually(done).Should(Receive(BeTrue()))
+		wg.Wait()
+	})
+	It("BlockUntilTag waits only for the tagged tickers", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+
+		done1 := make(chan bool, 1)
+		ft.Tag("first")
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Ω(tickingRoutine(done1)).Should(BeTrue())
+		}()
+		ft.BlockUntilTag("first", 1)
+
+		done2 := make(chan bool, 1)
+		ft.Tag("second")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Ω(tickingRoutine(done2)).Should(BeTrue())
+		}()
+		ft.BlockUntilTag("second", 1)
+
+		ft.Tick("first")
+		Eventually(done1).Should(Receive(BeTrue()))
+		Consistently(done2).ShouldNot(Receive())
+
+		ft.Tick("second")
+		Eventually(done2).Should(Receive(BeTrue()))
+		wg.Wait()
+	})
 	It("ticks immediately with an immediate argument", func() {
-		ft := NewFakeTicker(true)
+		ft := faketickers.NewFakeTicker(true)
+		defer ft.Stop()
+		done := make(chan bool, 1)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Ω(tickingRoutine(done)).Should(BeTrue())
+		}()
+		Eventually(done).Should(Receive(BeTrue()))
+		wg.Wait()
+	})
+})
+
+var _ = Describe("Ticker modes", func() {
+	It("ModeStrict (the default) blocks Tick until the receiver consumes it", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ticker := time.NewTicker(time.Hour)
+		ft.BlockUntil(1)
+
+		tickReturned := make(chan bool, 1)
+		go func() {
+			ft.Tick()
+			tickReturned <- true
+		}()
+		Consistently(tickReturned).ShouldNot(Receive())
+		Ω(ticker.C).Should(Receive())
+		Eventually(tickReturned).Should(Receive(BeTrue()))
+	})
+	It("ModeCoalescing collapses a burst of ticks into at most one pending tick", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ft.Mode(faketickers.ModeCoalescing)
+		ticker := time.NewTicker(time.Hour)
+		ft.BlockUntil(1)
+
+		ft.TickN(5) // Must not block even though nobody's reading yet.
+		Ω(ticker.C).Should(Receive())
+		Consistently(ticker.C).ShouldNot(Receive())
+	})
+	It("TickN delivers every strict tick in sequence", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ticker := time.NewTicker(time.Hour)
+		ft.BlockUntil(1)
+
+		received := make(chan int, 3)
+		go func() {
+			for i := 0; i < 3; i++ {
+				<-ticker.C
+				received <- i
+			}
+		}()
+		ft.TickN(3)
+		Eventually(received).Should(HaveLen(3))
+	})
+	It("Reset and Stop on the returned Ticker don't panic, and Reset leaves Tick delivery unchanged", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ticker := time.NewTicker(time.Minute)
+		ft.BlockUntil(1)
+
+		ticker.Reset(time.Second) // Must not panic mid-stream; has no effect on delivery, see TickerMode.
+		received := make(chan bool, 1)
+		go func() {
+			<-ticker.C
+			received <- true
+		}()
+		ft.Tick()
+		Eventually(received).Should(Receive(BeTrue()))
+
+		ticker.Stop() // Must not panic either.
+	})
+})
+
+var _ = Describe("Logical ticker source", func() {
+	It("fans a single pulse out to every subscribed ticker", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		src := ft.NewLogicalTickerSource(true)
+
+		done1 := make(chan bool, 1)
+		done2 := make(chan bool, 1)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			Ω(tickingRoutine(done1)).Should(BeTrue())
+		}()
+		go func() {
+			defer wg.Done()
+			Ω(tickingRoutine(done2)).Should(BeTrue())
+		}()
+		ft.BlockUntil(2)
+
+		src <- time.Now()
+		Eventually(done1).Should(Receive(BeTrue()))
+		Eventually(done2).Should(Receive(BeTrue()))
+		wg.Wait()
+	})
+	It("only fans out to tickers with the given tag", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		src := ft.NewLogicalTickerSource(true, "wanted")
+
+		ft.Tag("wanted")
+		done1 := make(chan bool, 1)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Ω(tickingRoutine(done1)).Should(BeTrue())
+		}()
+		ft.BlockUntilTag("wanted", 1)
+
+		ft.Tag("other")
+		done2 := make(chan bool, 1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Ω(tickingRoutine(done2)).Should(BeTrue())
+		}()
+		ft.BlockUntilTag("other", 1)
+
+		src <- time.Now()
+		Eventually(done1).Should(Receive(BeTrue()))
+		Consistently(done2).ShouldNot(Receive())
+
+		ft.Tick("other")
+		Eventually(done2).Should(Receive(BeTrue()))
+		wg.Wait()
+	})
+	It("drops a lossy pulse that a ticker isn't ready to receive", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		src := ft.NewLogicalTickerSource(false)
+
+		ticker := time.NewTicker(time.Hour)
+		ft.BlockUntil(1)
+
+		src <- time.Now() // Nobody's receiving; this must not block.
+		Consistently(ticker.C).ShouldNot(Receive())
+	})
+})
+
+var _ = Describe("Fake timers", func() {
+	It("fires time.After on Advance", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ft.StartTimers()
+		c := time.After(time.Minute)
+		Ω(c).ShouldNot(Receive())
+		ft.Advance(time.Minute)
+		Ω(c).Should(Receive())
+	})
+	It("fires AfterFunc callbacks in fire-time order", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ft.StartTimers()
+		var order []string
+		time.AfterFunc(time.Minute, func() { order = append(order, "slow") })
+		time.AfterFunc(time.Second, func() { order = append(order, "fast") })
+		ft.Advance(time.Minute)
+		Ω(order).Should(Equal([]string{"fast", "slow"}))
+	})
+	It("runs AfterFunc callbacks synchronously on Advance", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ft.StartTimers()
+		fired := false
+		time.AfterFunc(time.Second, func() { fired = true })
+		ft.Advance(time.Second)
+		Ω(fired).Should(BeTrue())
+	})
+	It("Timer.Stop reports standard library semantics and suppresses delivery", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ft.StartTimers()
+		timer := time.NewTimer(time.Minute)
+		Ω(timer.Stop()).Should(BeTrue())
+		Ω(timer.Stop()).Should(BeFalse())
+		ft.Advance(time.Hour)
+		Ω(timer.C).ShouldNot(Receive())
+	})
+	It("Timer.Reset reports standard library semantics", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ft.StartTimers()
+		timer := time.NewTimer(time.Second)
+		ft.Advance(time.Second)
+		Ω(timer.C).Should(Receive())
+		Ω(timer.Reset(time.Second)).Should(BeFalse()) // already fired
+	})
+	It("never delivers on real wall-clock time, only via Advance/SetNow", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ft.StartTimers()
+		c := time.After(time.Millisecond * 5)
+		time.Sleep(time.Millisecond * 50) // Not Consistently: it polls with the now-patched time.After.
+		Ω(c).ShouldNot(Receive())
+		ft.Advance(time.Millisecond * 5)
+		Ω(c).Should(Receive())
+	})
+})
+
+var _ = Describe("WaitContext", func() {
+	It("returns once minTickers have been created", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
 		defer ft.Stop()
 		done := make(chan bool, 1)
 		var wg sync.WaitGroup
@@ -114,15 +383,77 @@ var _ = Describe("Faketicker", func() {
 			defer wg.Done()
 			Ω(tickingRoutine(done)).Should(BeTrue())
 		}()
+		Ω(ft.WaitContext(context.Background(), 1)).Should(Succeed())
+		ft.Tick()
 		Eventually(done).Should(Receive(BeTrue()))
 		wg.Wait()
 	})
+	It("gives up with the context's error when the context is cancelled first", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Ω(ft.WaitContext(ctx, 1)).Should(MatchError(context.Canceled))
+	})
+})
+
+var _ = Describe("Scope", func() {
+	It("only sees tickers created by the scoping goroutine or its children", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+
+		var scoped *faketickers.FakeTickers
+		inScope := make(chan bool, 1)
+		done1 := make(chan bool, 1)
+		go func() {
+			scoped = ft.Scope()
+			inScope <- true
+			Ω(tickingRoutine(done1)).Should(BeTrue())
+		}()
+		Eventually(inScope).Should(Receive(BeTrue()))
+		scoped.BlockUntil(1) // Doesn't return until the scoped ticker exists.
+
+		done2 := make(chan bool, 1)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Ω(tickingRoutine(done2)).Should(BeTrue())
+		}()
+		ft.BlockUntil(1) // Root's view is just its own ticker now that it partitions from scoped.
+
+		scoped.Tick()
+		Eventually(done1).Should(Receive(BeTrue()))
+		Consistently(done2).ShouldNot(Receive())
+
+		ft.Tick()
+		Eventually(done2).Should(Receive(BeTrue()))
+		wg.Wait()
+	})
+	It("applies Tag only to tickers created within the scope", func() {
+		ft := faketickers.FakeTickers{}
+		ft.Start()
+		defer ft.Stop()
+
+		scoped := ft.Scope()
+		scoped.Tag("scoped")
+		done := make(chan bool, 1)
+		go func() {
+			Ω(tickingRoutine(done)).Should(BeTrue())
+		}()
+		scoped.BlockUntil(1)
+
+		scoped.Tick("scoped")
+		Eventually(done).Should(Receive(BeTrue()))
+	})
 })
 
 var _ = Describe("InstantSleeps", func() {
 	It("forces a long time.Sleep to take no time at all.", func() {
 		start := time.Now()
-		p := InstantSleeps()
+		p := faketickers.InstantSleeps()
 		time.Sleep(time.Hour)
 		p.Stop()
 		Ω(time.Now()).Should(BeTemporally("~", start, time.Minute*10))
@@ -0,0 +1,82 @@
+package faketickers
+
+import (
+	"sync"
+	"time"
+)
+
+// logicalTickerSource is one shared pulse installed via
+// NewLogicalTickerSource. Every fake ticker created (matching tag, if
+// one was given) after the source was installed subscribes to it: a
+// value sent on the source's channel is forwarded to each
+// subscriber's own ticker channel by the goroutine running forward.
+type logicalTickerSource struct {
+	tag      string
+	hasTag   bool
+	lossless bool
+	in       chan time.Time
+
+	subMut sync.Mutex
+	subs   []*chan time.Time
+}
+
+func (s *logicalTickerSource) subscribe(tag string, c *chan time.Time) {
+	if s.hasTag && tag != s.tag {
+		return
+	}
+	s.subMut.Lock()
+	defer s.subMut.Unlock()
+	s.subs = append(s.subs, c)
+}
+
+// forward reads pulses off the source's input channel and fans each
+// one out to every subscriber, until the input channel is closed by
+// stop.
+func (s *logicalTickerSource) forward() {
+	for now := range s.in {
+		s.subMut.Lock()
+		subs := make([]*chan time.Time, len(s.subs))
+		copy(subs, s.subs)
+		s.subMut.Unlock()
+		for _, c := range subs {
+			if s.lossless {
+				*c <- now
+			} else {
+				select {
+				case *c <- now:
+				default: // Drop, matching time.Ticker's own buffered-size-1 behavior.
+				}
+			}
+		}
+	}
+}
+
+func (s *logicalTickerSource) stop() {
+	close(s.in)
+}
+
+// NewLogicalTickerSource returns a channel that fans a single pulse
+// out to every fake ticker created (after this call, while
+// FakeTickers is started), tag-filtered if tag is given. Sending on
+// the returned channel drives every subscribed ticker at once, which
+// is handy for simulating coordinated schedulers where many
+// independent components must tick together from one deterministic
+// source rather than each other's own Tick() calls.
+//
+// lossless forwarding blocks until every subscriber has consumed the
+// pulse, giving strict determinism; non-lossless forwarding mirrors
+// time.Ticker's normal drop-if-not-ready behavior.
+func (t *FakeTickers) NewLogicalTickerSource(lossless bool, tag ...string) chan time.Time {
+	source := &logicalTickerSource{lossless: lossless, in: make(chan time.Time)}
+	if len(tag) > 0 {
+		source.tag = tag[0]
+		source.hasTag = true
+	}
+
+	t.tickerMut.Lock()
+	t.sources = append(t.sources, source)
+	t.tickerMut.Unlock()
+
+	go source.forward()
+	return source.in
+}
@@ -0,0 +1,180 @@
+package faketickers
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// scopeSettings holds the Tag/Mode a single Scope() has set, keyed in
+// FakeTickers.scopes by the goroutine ID that called Scope(). It's
+// separate from FakeTickers.tag/mode so that sibling scopes (and the
+// root) can each have their own without a lock per NewTicker call
+// turning into a map of *FakeTickers.
+type scopeSettings struct {
+	tag  string
+	mode TickerMode
+}
+
+// Scope returns a child FakeTickers whose Tag/Mode calls, and whose
+// Tick/TickN/BlockUntil*/Wait*, only see the subset of tickers created
+// by the calling goroutine or one it (transitively) spawns with go.
+// The underlying time.NewTicker patch is still the single process-wide
+// one installed by the root's Start() -- Scope() doesn't install a
+// second one -- so newTicker works out which scope (if any) a caller
+// belongs to by walking the goroutine's creation chain.
+//
+// This is for parallel tests: each t.Parallel() subtest calls Scope()
+// once at the top of its own goroutine and gets back something that
+// behaves like its own private FakeTickers, without the subtests'
+// NewTicker/Tick calls interfering with each other.
+//
+// Only Tag, Mode and the ticker-counting/sending methods (Tick, TickN,
+// BlockUntil, BlockUntilTag, Wait, WaitContext) are scope-aware.
+// Start/Stop, the virtual clock (Now/Advance/SetNow/StartTimers) and
+// NewLogicalTickerSource are shared, process-wide state and must only
+// be called on the root FakeTickers.
+func (t *FakeTickers) Scope() *FakeTickers {
+	root := t.registryRoot()
+	gid := goroutineID()
+
+	root.tickerMut.Lock()
+	if root.scopes == nil {
+		root.scopes = map[uint64]*scopeSettings{}
+	}
+	root.scopes[gid] = &scopeSettings{}
+	root.tickerMut.Unlock()
+
+	return &FakeTickers{scopeParent: root, scopeRoot: gid}
+}
+
+// registryRoot returns the FakeTickers that actually owns the ticker
+// slice, mutex, cond and patch guard -- itself, unless it was returned
+// by Scope(), in which case it's the root FakeTickers behind that
+// Scope() call (Scope() called on a scope hands back a sibling scope of
+// the same root, not a nested one).
+func (t *FakeTickers) registryRoot() *FakeTickers {
+	if t.scopeParent != nil {
+		return t.scopeParent.registryRoot()
+	}
+	return t
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed out of
+// the header line runtime.Stack prints ("goroutine 123 [running]:").
+// The runtime doesn't expose goroutine IDs any other way.
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	return parseGoroutineID(buf[:n])
+}
+
+func parseGoroutineID(line []byte) uint64 {
+	line = bytes.TrimPrefix(line, []byte("goroutine "))
+	end := bytes.IndexByte(line, ' ')
+	if end < 0 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(line[:end]), 10, 64)
+	return id
+}
+
+// goroutineAncestry dumps every live goroutine's stack and returns a
+// map from goroutine ID to the ID of the goroutine that spawned it,
+// read off each block's "created by ... in goroutine N" line. A
+// goroutine with no recorded creator -- the main goroutine, or one
+// whose creator has since exited -- is simply absent from the map.
+//
+// The "in goroutine N" suffix is only present on Go 1.21+; on older
+// toolchains the map comes back empty and Scope() degrades to only
+// recognizing the exact goroutine that called it, not goroutines it
+// spawns.
+func goroutineAncestry() map[uint64]uint64 {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	parents := map[uint64]uint64{}
+	for _, block := range bytes.Split(buf, []byte("\n\n")) {
+		id := parseGoroutineID(block)
+		if id == 0 {
+			continue
+		}
+		marker := []byte("in goroutine ")
+		idx := bytes.LastIndex(block, marker)
+		if idx < 0 {
+			continue
+		}
+		rest := block[idx+len(marker):]
+		end := bytes.IndexAny(rest, "\n ")
+		if end < 0 {
+			end = len(rest)
+		}
+		parent, err := strconv.ParseUint(string(rest[:end]), 10, 64)
+		if err != nil {
+			continue
+		}
+		parents[id] = parent
+	}
+	return parents
+}
+
+// goroutineStatuses dumps every live goroutine's stack and returns a
+// map from goroutine ID to the status runtime.Stack reports for it --
+// e.g. "running", "chan receive", "select" -- read off each block's
+// "goroutine N [status]:" header line, with anything after a comma
+// (such as a ", 5 minutes" wait time) dropped. Used by parkedCount to tell
+// whether a ticker's creator goroutine has actually reached a channel
+// receive, not just that NewTicker returned.
+func goroutineStatuses() map[uint64]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	statuses := map[uint64]string{}
+	for _, block := range bytes.Split(buf, []byte("\n\n")) {
+		id := parseGoroutineID(block)
+		if id == 0 {
+			continue
+		}
+		open := bytes.IndexByte(block, '[')
+		shut := bytes.IndexByte(block, ']')
+		if open < 0 || shut < open {
+			continue
+		}
+		status := block[open+1 : shut]
+		if comma := bytes.IndexByte(status, ','); comma >= 0 {
+			status = status[:comma]
+		}
+		statuses[id] = string(status)
+	}
+	return statuses
+}
+
+// descendsFrom reports whether goroutine id is root itself or was
+// (transitively) spawned by it, per the creation chain in ancestry.
+func descendsFrom(ancestry map[uint64]uint64, root, id uint64) bool {
+	for hops := 0; id != 0 && hops <= len(ancestry); hops++ {
+		if id == root {
+			return true
+		}
+		parent, ok := ancestry[id]
+		if !ok {
+			return false
+		}
+		id = parent
+	}
+	return false
+}
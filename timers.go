@@ -0,0 +1,190 @@
+package faketickers
+
+import (
+	"container/heap"
+	"time"
+	"unsafe"
+
+	"bou.ke/monkey"
+)
+
+// fakeTimer is one pending (or already fired) fake time.Timer. It is
+// kept in a FakeTickers' timerQueue, a min-heap ordered by fireAt, so
+// that Advance/SetNow can fire due timers in the order they'd have
+// fired in production.
+//
+// real is a genuine time.Timer that we hand back directly instead of
+// fabricating our own, so that (*time.Timer).Stop and .Reset return
+// the standard library's values for free -- no monkey patching of
+// those tiny methods required (which the inliner tends to compile
+// away anyway). Reset only re-arms real, on wall-clock time; it never
+// moves fireAt, and a timer reset after it fired is not scheduled on
+// the virtual clock again. Crucially, real is armed for a duration far longer
+// than any test run rather than for the duration the caller actually
+// asked for (fireAt tracks that instead): arming it for real would let
+// it fire on its own, on real wall-clock time, independently of
+// Advance/SetNow -- precisely the bug a virtual clock exists to avoid.
+// We use real.Stop() ourselves, right before delivering a fake tick,
+// as an atomic check for "has the caller already stopped this".
+type fakeTimer struct {
+	fireAt time.Time
+	fn     func()
+	real   *time.Timer
+	index  int
+}
+
+// writableChan reinterprets a time.Timer's receive-only C field as a
+// sendable channel. This is safe: the two types share the same
+// underlying representation, and direction is a compile-time-only
+// restriction Go puts on the field, not something the channel itself
+// enforces. We need it because we're the one side allowed to fire a
+// fake timer.
+func writableChan(c <-chan time.Time) chan time.Time {
+	return *(*chan time.Time)(unsafe.Pointer(&c))
+}
+
+// timerQueue is a container/heap of *fakeTimer ordered by fireAt.
+type timerQueue []*fakeTimer
+
+func (q timerQueue) Len() int           { return len(q) }
+func (q timerQueue) Less(i, j int) bool { return q[i].fireAt.Before(q[j].fireAt) }
+func (q timerQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i]; q[i].index = i; q[j].index = j }
+func (q *timerQueue) Push(x interface{}) {
+	ft := x.(*fakeTimer)
+	ft.index = len(*q)
+	*q = append(*q, ft)
+}
+func (q *timerQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	ft := old[n-1]
+	old[n-1] = nil
+	ft.index = -1
+	*q = old[:n-1]
+	return ft
+}
+
+// virtuallyForever is the real duration fakeTimer's underlying
+// time.Timer is armed for -- long enough that no test run will make it
+// expire for real, while still leaving Stop/Reset's return values
+// telling the truth about whether the timer has genuinely been
+// stopped or reset since creation.
+const virtuallyForever = time.Duration(1<<62) * time.Nanosecond
+
+// scheduleTimer registers a fake timer due to fire d after the
+// current virtual time. If f is non-nil, it's called (instead of a
+// value being sent on the timer's channel) when the timer fires,
+// matching time.AfterFunc -- whose returned Timer.C is likewise nil.
+//
+// timerGuard is nil when StartTimers hasn't patched time.NewTimer --
+// which is always the case for a FakeClock, since it calls this
+// directly instead of going through the patch -- so real.Stop/Reset
+// work unconditionally either way.
+func (t *FakeTickers) scheduleTimer(d time.Duration, f func()) *time.Timer {
+	t.timerMut.Lock()
+	defer t.timerMut.Unlock()
+	if t.timerGuard != nil {
+		t.timerGuard.Unpatch()
+	}
+	real := time.NewTimer(virtuallyForever)
+	if t.timerGuard != nil {
+		t.timerGuard.Restore()
+	}
+	if f != nil {
+		real.C = nil
+	} else {
+		real.C = make(chan time.Time, 1)
+	}
+	heap.Push(&t.timerQueue, &fakeTimer{fireAt: t.now.Add(d), fn: f, real: real})
+	return real
+}
+
+func (t *FakeTickers) newTimer(d time.Duration) *time.Timer {
+	return t.scheduleTimer(d, nil)
+}
+
+func (t *FakeTickers) after(d time.Duration) <-chan time.Time {
+	return t.scheduleTimer(d, nil).C
+}
+
+func (t *FakeTickers) afterFunc(d time.Duration, f func()) *time.Timer {
+	return t.scheduleTimer(d, f)
+}
+
+// Now returns the fake clock's current virtual time, which starts at
+// the real time.Now() when Start() is called and only moves via
+// Advance/SetNow.
+func (t *FakeTickers) Now() time.Time {
+	t.timerMut.Lock()
+	defer t.timerMut.Unlock()
+	return t.now
+}
+
+// Advance moves the fake clock forward by d, firing (in fire-time
+// order) any fake timers that come due. AfterFunc callbacks run
+// synchronously, in the caller's goroutine, before Advance returns.
+func (t *FakeTickers) Advance(d time.Duration) {
+	t.SetNow(t.Now().Add(d))
+}
+
+// SetNow sets the fake clock's virtual time directly and fires (in
+// fire-time order) any fake timers whose deadline is now at or before
+// tm. A timer the caller has already stopped is skipped. A timer reset
+// before its deadline is still delivered at that original deadline,
+// unless the real timer Reset re-armed has already expired for real,
+// in which case it is skipped.
+func (t *FakeTickers) SetNow(tm time.Time) {
+	t.timerMut.Lock()
+	t.now = tm
+	var due []*fakeTimer
+	for len(t.timerQueue) > 0 && !t.timerQueue[0].fireAt.After(tm) {
+		due = append(due, heap.Pop(&t.timerQueue).(*fakeTimer))
+	}
+	t.timerMut.Unlock()
+
+	for _, ft := range due {
+		if !ft.real.Stop() {
+			continue
+		}
+		if ft.fn != nil {
+			ft.fn()
+			continue
+		}
+		select {
+		case writableChan(ft.real.C) <- tm:
+		default:
+		}
+	}
+}
+
+// initTimers resets the virtual clock and timer bookkeeping. It runs
+// on every Start(), even if StartTimers() is never called, so Now()
+// and Advance() are always safe to use.
+func (t *FakeTickers) initTimers() {
+	t.now = time.Now()
+	t.timerQueue = nil
+}
+
+// StartTimers extends an already-started FakeTickers to also fake
+// time.NewTimer, time.After and time.AfterFunc, driven by the same
+// virtual clock as Advance and SetNow.
+//
+// This is opt-in and separate from Start() because it patches
+// time.After, which test infrastructure (including Gomega's Eventually
+// and Consistently) uses for its own polling. Call it only for tests
+// that assert on fake timers directly, and prefer plain channel
+// receives over Eventually/Consistently for those assertions.
+func (t *FakeTickers) StartTimers() {
+	t.timerGuard = monkey.Patch(time.NewTimer, t.newTimer)
+	t.afterGuard = monkey.Patch(time.After, t.after)
+	t.afterFuncGuard = monkey.Patch(time.AfterFunc, t.afterFunc)
+}
+
+func (t *FakeTickers) stopTimers() {
+	if t.timerGuard == nil {
+		return
+	}
+	t.timerGuard.Unpatch()
+	t.afterGuard.Unpatch()
+	t.afterFuncGuard.Unpatch()
+}